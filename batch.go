@@ -0,0 +1,191 @@
+package skiplist
+
+// batchOpKind identifies the kind of operation recorded by a Batch entry.
+type batchOpKind int
+
+const (
+	batchPut batchOpKind = iota
+	batchDelete
+)
+
+// batchOp is a single recorded Set or Remove, queued for later application.
+type batchOp struct {
+	kind  batchOpKind
+	key   Skey
+	value interface{}
+}
+
+// Batch collects a group of Set/Remove operations so SkipList.Write can apply them
+// as a single atomic unit, observed by readers either all together or not at all.
+// Modeled on leveldb.Batch.
+// A zero-value Batch is trivially sorted (it has no out-of-order keys to speak
+// of), so unsortedness is tracked as the negative "has this batch seen a
+// decreasing key" rather than a positive "is this batch sorted" - that way the
+// zero value of Batch, not just the result of Reset, takes the sorted fast path.
+type Batch struct {
+	ops      []batchOp
+	unsorted bool
+}
+
+// Put records a Set of key to value in the batch.
+func (b *Batch) Put(key Skey, value interface{}) {
+	b.checkSorted(key)
+	b.ops = append(b.ops, batchOp{kind: batchPut, key: key, value: value})
+}
+
+// Delete records a Remove of key in the batch.
+func (b *Batch) Delete(key Skey) {
+	b.checkSorted(key)
+	b.ops = append(b.ops, batchOp{kind: batchDelete, key: key})
+}
+
+// Len returns the number of operations recorded in the batch.
+func (b *Batch) Len() int {
+	return len(b.ops)
+}
+
+// Reset clears the batch so it can be reused.
+func (b *Batch) Reset() {
+	b.ops = b.ops[:0]
+	b.unsorted = false
+}
+
+// checkSorted tracks whether keys have been appended in non-decreasing order, so
+// Write can take the sorted fast path without a separate pre-pass over b.ops.
+func (b *Batch) checkSorted(key Skey) {
+	if n := len(b.ops); n > 0 && !b.unsorted && key.Less(b.ops[n-1].key) {
+		b.unsorted = true
+	}
+}
+
+// Replay re-applies every operation in the batch against list, taking list's own
+// lock for each operation. It's the unordered, no-fast-path counterpart to
+// SkipList.Write, for callers that want Set/Remove's normal per-op semantics. It
+// must not be called while list's lock is already held, since Set and Remove
+// acquire it themselves.
+func (b *Batch) Replay(list *SkipList) error {
+	for _, op := range b.ops {
+		switch op.kind {
+		case batchPut:
+			list.Set(op.key, op.value)
+		case batchDelete:
+			list.Remove(op.key)
+		}
+	}
+	return nil
+}
+
+// Write applies every operation recorded in b to list under a single
+// mutex.Lock() acquisition, so concurrent readers observe either all of the
+// batch's writes or none of them. If b's keys were appended in non-decreasing
+// order, Write takes a sorted fast path: it walks the list once, left to right,
+// reusing the search frontier between successive inserts instead of re-searching
+// from the head for every key.
+func (list *SkipList) Write(b *Batch) error {
+	list.mutex.Lock()
+	defer list.mutex.Unlock()
+
+	if !b.unsorted {
+		list.writeSorted(b)
+		return nil
+	}
+
+	for _, op := range b.ops {
+		switch op.kind {
+		case batchPut:
+			list.set(op.key, op.value)
+		case batchDelete:
+			list.remove(op.key)
+		}
+	}
+	return nil
+}
+
+// writeSorted applies b's operations assuming its keys are non-decreasing, sharing
+// a single left-to-right search frontier across them instead of restarting from the
+// head for every operation.
+func (list *SkipList) writeSorted(b *Batch) {
+	prevs := make([]*elementNode, list.maxLevel)
+	for i := range prevs {
+		prevs[i] = &list.elementNode
+	}
+
+	for _, op := range b.ops {
+		list.advancePrevElementNodes(prevs, op.key)
+
+		switch op.kind {
+		case batchPut:
+			if element := prevs[0].next[0]; element != nil && !element.key.Great(op.key) && !element.key.Less(op.key) {
+				list.archiveVersion(element)
+				revived := element.tombstone
+				element.value = op.value
+				element.seq = list.nextSeq()
+				element.tombstone = false
+				if revived {
+					// See skiplist.go's set: clear the tombstone before touching the
+					// filter so a rebuild triggered by this call sees the element as
+					// live instead of dropping the revived key.
+					list.addToBloom(op.key)
+				}
+				continue
+			}
+
+			element := &Element{
+				elementNode: elementNode{next: make([]*Element, list.randLevel())},
+				key:         op.key,
+				value:       op.value,
+				seq:         list.nextSeq(),
+			}
+
+			// prevs is left pointing before the new element, so a later op on the
+			// same key (keys are non-decreasing, not strictly increasing) finds it.
+			for i := range element.next {
+				element.next[i] = prevs[i].next[i]
+				prevs[i].next[i] = element
+			}
+
+			list.Length++
+			list.addToBloom(op.key)
+		case batchDelete:
+			element := prevs[0].next[0]
+			if element == nil || element.key.Great(op.key) || element.key.Less(op.key) || element.tombstone {
+				continue
+			}
+
+			list.removeFromBloom(element.key)
+
+			if len(list.snapshotRefs) > 0 {
+				list.archiveVersion(element)
+				element.seq = list.nextSeq()
+				element.tombstone = true
+				list.Length--
+				continue
+			}
+
+			for k, v := range element.next {
+				prevs[k].next[k] = v
+			}
+
+			list.Length--
+		}
+	}
+}
+
+// advancePrevElementNodes behaves like getPrevElementNodes but starts its search
+// from an existing frontier instead of the list head, so a sorted run of keys shares
+// one left-to-right traversal instead of re-searching from the head every time.
+func (list *SkipList) advancePrevElementNodes(prevs []*elementNode, key Skey) {
+	var next *Element
+
+	for i := list.maxLevel - 1; i >= 0; i-- {
+		prev := prevs[i]
+		next = prev.next[i]
+
+		for next != nil && next.key.Less(key) {
+			prev = &next.elementNode
+			next = next.next[i]
+		}
+
+		prevs[i] = prev
+	}
+}
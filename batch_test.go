@@ -0,0 +1,124 @@
+package skiplist
+
+import "testing"
+
+func TestBatchWriteSorted(t *testing.T) {
+	list := New()
+	list.Set(MySkey(5), 5)
+
+	var b Batch
+	b.Delete(MySkey(5))
+	b.Put(MySkey(10), 1)
+	b.Put(MySkey(20), 2)
+	b.Put(MySkey(30), 3)
+
+	if b.Len() != 4 {
+		t.Fatalf("wrong batch length: got %v", b.Len())
+	}
+
+	if err := list.Write(&b); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+	checkSanity(list, t)
+
+	if list.Get(MySkey(5)) != nil {
+		t.Fatal("key 5 should have been deleted by the batch")
+	}
+	if v := list.Get(MySkey(10)); v == nil || v.value.(int) != 1 {
+		t.Fatal("wrong value for key 10 after batch write")
+	}
+	if v := list.Get(MySkey(30)); v == nil || v.value.(int) != 3 {
+		t.Fatal("wrong value for key 30 after batch write")
+	}
+	if list.Length != 3 {
+		t.Fatalf("wrong length after batch write: got %v", list.Length)
+	}
+}
+
+func TestBatchWriteUnsorted(t *testing.T) {
+	list := New()
+
+	var b Batch
+	b.Put(MySkey(30), 3)
+	b.Put(MySkey(10), 1)
+	b.Put(MySkey(20), 2)
+
+	if err := list.Write(&b); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+	checkSanity(list, t)
+
+	if v := list.Get(MySkey(10)); v == nil || v.value.(int) != 1 {
+		t.Fatal("wrong value for key 10 after unsorted batch write")
+	}
+	if list.Length != 3 {
+		t.Fatalf("wrong length after unsorted batch write: got %v", list.Length)
+	}
+}
+
+func TestBatchWriteSortedUpdatesBloom(t *testing.T) {
+	list := NewWithBloom(100, 0.01)
+
+	var b Batch
+	b.Put(MySkey(10), 1)
+	b.Put(MySkey(20), 2)
+	b.Put(MySkey(30), 3)
+
+	if err := list.Write(&b); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+
+	if v := list.Get(MySkey(20)); v == nil || v.value.(int) != 2 {
+		t.Fatalf("expected sorted batch put to be visible through the bloom filter, got %v", v)
+	}
+}
+
+func TestBatchWriteSortedRespectsSnapshot(t *testing.T) {
+	list := New()
+	list.Set(MySkey(10), 1)
+	list.Set(MySkey(20), 2)
+
+	snap := list.Snapshot()
+	defer snap.Release()
+
+	var b Batch
+	b.Put(MySkey(10), 99)
+	b.Delete(MySkey(20))
+
+	if err := list.Write(&b); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+
+	if v := snap.Get(MySkey(10)); v == nil || v.Value().(int) != 1 {
+		t.Fatalf("snapshot should still see the old value for key 10 after a sorted batch write, got %v", v)
+	}
+	if v := snap.Get(MySkey(20)); v == nil || v.Value().(int) != 2 {
+		t.Fatalf("snapshot should still see key 20 after a sorted batch delete, got %v", v)
+	}
+}
+
+func TestBatchReset(t *testing.T) {
+	var b Batch
+	b.Put(MySkey(10), 1)
+	b.Reset()
+
+	if b.Len() != 0 {
+		t.Fatalf("expected empty batch after Reset, got len %v", b.Len())
+	}
+}
+
+func TestBatchReplay(t *testing.T) {
+	list := New()
+
+	var b Batch
+	b.Put(MySkey(10), 1)
+	b.Put(MySkey(20), 2)
+
+	if err := b.Replay(list); err != nil {
+		t.Fatalf("Replay returned error: %v", err)
+	}
+
+	if v := list.Get(MySkey(20)); v == nil || v.value.(int) != 2 {
+		t.Fatal("wrong value for key 20 after Replay")
+	}
+}
@@ -0,0 +1,132 @@
+package skiplist
+
+import (
+	"hash/fnv"
+	"math"
+)
+
+// bloomMaxCount is the saturation point of each 4-bit counter.
+const bloomMaxCount = 15
+
+// countingBloom is a counting Bloom filter: like a regular Bloom filter, but each bit
+// is a small counter instead, so a key can be un-added on Remove without risking false
+// negatives for other keys sharing a bucket. Counters are 4 bits wide, packed two to a
+// byte, and saturate at bloomMaxCount rather than overflow.
+type countingBloom struct {
+	counters []byte
+	m        uint64 // number of counters
+	k        uint64 // number of hash functions (buckets touched per key)
+}
+
+// newCountingBloom sizes a filter for expectedN keys at the given target false
+// positive rate, using the standard optimal-m/optimal-k formulas.
+func newCountingBloom(expectedN int, fpRate float64) *countingBloom {
+	if expectedN < 1 {
+		expectedN = 1
+	}
+	if fpRate <= 0 || fpRate >= 1 {
+		fpRate = 0.01
+	}
+
+	n := float64(expectedN)
+	m := uint64(math.Ceil(-n * math.Log(fpRate) / (math.Ln2 * math.Ln2)))
+	if m < 1 {
+		m = 1
+	}
+	k := uint64(math.Round(float64(m) / n * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+
+	return &countingBloom{
+		counters: make([]byte, (m+1)/2),
+		m:        m,
+		k:        k,
+	}
+}
+
+// indexes computes the k bucket indices for data via double hashing
+// (Kirsch-Mitzenmacher): g_i(x) = h1(x) + i*h2(x) mod m.
+func (b *countingBloom) indexes(data string) []uint64 {
+	h1 := fnv.New64a()
+	h1.Write([]byte(data))
+	sum1 := h1.Sum64()
+
+	h2 := fnv.New32a()
+	h2.Write([]byte(data))
+	sum2 := uint64(h2.Sum32())
+	if sum2 == 0 {
+		sum2 = 1 // a zero step would degenerate every g_i to the same bucket
+	}
+
+	idx := make([]uint64, b.k)
+	for i := uint64(0); i < b.k; i++ {
+		idx[i] = (sum1 + i*sum2) % b.m
+	}
+	return idx
+}
+
+func (b *countingBloom) get(i uint64) byte {
+	by := b.counters[i/2]
+	if i%2 == 0 {
+		return by & 0x0F
+	}
+	return by >> 4
+}
+
+func (b *countingBloom) set(i uint64, v byte) {
+	by := b.counters[i/2]
+	if i%2 == 0 {
+		b.counters[i/2] = (by & 0xF0) | (v & 0x0F)
+	} else {
+		b.counters[i/2] = (by & 0x0F) | (v << 4)
+	}
+}
+
+// add increments the counter at every bucket hashed from data, saturating at
+// bloomMaxCount. It reports whether any bucket was already saturated, which the
+// caller treats as a signal that the filter has drifted under churn and should be
+// rebuilt from scratch.
+func (b *countingBloom) add(data string) (saturated bool) {
+	for _, i := range b.indexes(data) {
+		c := b.get(i)
+		if c >= bloomMaxCount {
+			saturated = true
+			continue
+		}
+		b.set(i, c+1)
+	}
+	return saturated
+}
+
+// remove decrements the counter at every bucket hashed from data. A bucket already at
+// zero, or stuck at the saturation cap where counts are no longer trustworthy, is left
+// untouched.
+func (b *countingBloom) remove(data string) {
+	for _, i := range b.indexes(data) {
+		c := b.get(i)
+		if c == 0 || c >= bloomMaxCount {
+			continue
+		}
+		b.set(i, c-1)
+	}
+}
+
+// mayContain reports whether data could be in the set. false is certain; true may be a
+// false positive.
+func (b *countingBloom) mayContain(data string) bool {
+	for _, i := range b.indexes(data) {
+		if b.get(i) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// reset zeroes every counter. Used by SkipList.Rebuild to recompute the filter from the
+// list's current contents instead of its accumulated history of adds and removes.
+func (b *countingBloom) reset() {
+	for i := range b.counters {
+		b.counters[i] = 0
+	}
+}
@@ -0,0 +1,49 @@
+package concurrent
+
+import "sync/atomic"
+
+// nilOffset marks the absence of a node. Offset 0 is reserved for it, so real
+// nodes are allocated starting at offset 1.
+const nilOffset uint32 = 0
+
+// arena is an append-only, fixed-capacity registry of nodes, handing out uint32
+// offsets instead of *node pointers. Every next-pointer in the skip list is one of
+// these offsets (packed with a deletion-mark bit, see pack/unpack), so towers store
+// 8-byte atomic words rather than raw pointers, and the whole skip list can be
+// capacity-bounded and rotated as a unit the way a Pebble/Badger memtable is.
+//
+// Nodes themselves are still ordinary heap-allocated Go values reached through
+// buf — this is a slice-of-pointers registry, not an unsafe flat byte arena, so it
+// does not avoid the per-node allocation or GC write-barrier cost of the parent
+// package's []*Element design.
+type arena struct {
+	buf       []*node
+	allocated uint32
+}
+
+// newArena creates an arena that can hold up to capacity nodes.
+func newArena(capacity int) *arena {
+	a := &arena{buf: make([]*node, capacity+1)}
+	a.allocated = 1 // offset 0 is reserved for nilOffset
+	return a
+}
+
+// alloc reserves the next free slot for n and returns its offset. ok is false if
+// the arena is full; callers must handle this (e.g. by rotating to a fresh
+// SkipList), the same way a Pebble/Badger memtable does when its arena fills up.
+func (a *arena) alloc(n *node) (offset uint32, ok bool) {
+	off := atomic.AddUint32(&a.allocated, 1) - 1
+	if int(off) >= len(a.buf) {
+		return 0, false
+	}
+	a.buf[off] = n
+	return off, true
+}
+
+// at returns the node at offset, or nil for nilOffset.
+func (a *arena) at(offset uint32) *node {
+	if offset == nilOffset {
+		return nil
+	}
+	return a.buf[offset]
+}
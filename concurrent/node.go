@@ -0,0 +1,47 @@
+package concurrent
+
+import "sync/atomic"
+
+// node is an arena-allocated skip list entry. Its tower of next links are atomic
+// uint64 values, each packing a uint32 arena offset with a low-order deletion-mark
+// bit (see pack/unpack), manipulated only via atomic.CompareAndSwapUint64.
+type node struct {
+	key Skey
+	// value is boxed behind a *interface{} rather than stored directly in the
+	// atomic.Value: atomic.Value panics if two Stores disagree on concrete type,
+	// which an arbitrary interface{} overwrite (e.g. Set(k, 42) then Set(k, "x"))
+	// would trip constantly. Every Store here uses the same concrete type
+	// (*interface{}), so heterogeneous overwrites are safe.
+	value atomic.Value
+	next  []uint64 // len(next) == this node's level; next[0] also carries the mark bit
+}
+
+func newNode(key Skey, value interface{}, level int) *node {
+	n := &node{key: key, next: make([]uint64, level)}
+	n.value.Store(&value)
+	return n
+}
+
+// pack combines an arena offset and deletion-mark bit into a single uint64 that
+// can be swapped atomically.
+func pack(offset uint32, marked bool) uint64 {
+	v := uint64(offset) << 1
+	if marked {
+		v |= 1
+	}
+	return v
+}
+
+// unpack splits a packed next-pointer back into its arena offset and mark bit.
+func unpack(packed uint64) (offset uint32, marked bool) {
+	return uint32(packed >> 1), packed&1 == 1
+}
+
+// marked reports whether this node has been logically deleted. Deletion only ever
+// marks the bottom-level pointer; that single bit is the linearization point other
+// goroutines check to treat the node as gone, regardless of whether it has been
+// physically unlinked from every level yet.
+func (n *node) marked() bool {
+	_, m := unpack(atomic.LoadUint64(&n.next[0]))
+	return m
+}
@@ -0,0 +1,281 @@
+// Package concurrent provides a lock-free skip list, modeled on the inline skip
+// lists used by Pebble and Badger memtables. Unlike the mutex-guarded SkipList in
+// the parent package, Get/Set/Remove here never take a lock: node towers are
+// linked with atomic.CompareAndSwapUint64 over arena offsets, following the
+// classic Herlihy-style lock-free skip list (find predecessors/successors at
+// every level, then CAS the new node in bottom-up, retrying any level whose
+// predecessor changed). Deletion is logical-mark-then-physical-unlink: Remove
+// marks the bottom-level next pointer, and whichever goroutine's search next
+// passes through the marked node unlinks it.
+//
+// Nodes are referenced by uint32 arena offset rather than *node, so tower links
+// are plain 8-byte atomic words; see arena for how that registry is actually built
+// (a slice of Go pointers, not an unsafe byte arena), and the accompanying caveat
+// about what GC cost it does and doesn't avoid.
+package concurrent
+
+import (
+	"sync/atomic"
+)
+
+// Skey orders keys for the skip list. It mirrors the parent package's Skey
+// methods, minus FilterValue. Its methods take this package's Skey, so a key type
+// written for the parent package does not satisfy it as is.
+type Skey interface {
+	Great(other Skey) bool
+	GreatE(other Skey) bool
+	Less(other Skey) bool
+	LessE(other Skey) bool
+}
+
+const (
+	// DefaultMaxLevel is the maximum number of levels created for a new SkipList.
+	DefaultMaxLevel int = 18
+	// DefaultArenaCapacity is the default number of nodes a SkipList's arena can
+	// hold before Set starts returning ErrArenaFull.
+	DefaultArenaCapacity int = 1 << 20
+)
+
+// ErrArenaFull is returned by Set when the SkipList's fixed-capacity arena has no
+// room left for a new node. Callers should rotate to a fresh SkipList, the same
+// way a Pebble/Badger memtable does once its arena fills up.
+type ErrArenaFull struct{}
+
+func (ErrArenaFull) Error() string { return "concurrent: arena is full" }
+
+// SkipList is a lock-free, arena-backed skip list safe for concurrent use by
+// multiple goroutines without any external synchronization.
+type SkipList struct {
+	head     *node
+	arena    *arena
+	maxLevel int
+	rng      uint64 // xorshift64 state, advanced via CAS; never zero
+	length   int64  // atomic approximate count of live keys
+}
+
+// New creates a SkipList with DefaultMaxLevel and DefaultArenaCapacity.
+func New() *SkipList {
+	return NewWithOptions(DefaultMaxLevel, DefaultArenaCapacity)
+}
+
+// NewWithOptions creates a SkipList whose arena can hold up to arenaCapacity
+// nodes, with towers up to maxLevel tall.
+func NewWithOptions(maxLevel, arenaCapacity int) *SkipList {
+	if maxLevel < 1 || maxLevel > 64 {
+		panic("concurrent: maxLevel must be a positive integer <= 64")
+	}
+
+	return &SkipList{
+		head:     &node{next: make([]uint64, maxLevel)},
+		arena:    newArena(arenaCapacity),
+		maxLevel: maxLevel,
+		rng:      0x9e3779b97f4a7c15, // arbitrary non-zero seed
+	}
+}
+
+// Len returns an approximate count of live keys; concurrent Sets/Removes may
+// race with the read.
+func (s *SkipList) Len() int {
+	return int(atomic.LoadInt64(&s.length))
+}
+
+// Get returns the value for key and true if present, or (nil, false) otherwise.
+// Lock-free: it only ever follows atomic loads, never a CAS, so it can't block or
+// be blocked by concurrent writers.
+func (s *SkipList) Get(key Skey) (interface{}, bool) {
+	pred := s.head
+
+	for level := s.maxLevel - 1; level >= 0; level-- {
+		curr := s.nextOffset(pred, level)
+
+		for curr != nilOffset {
+			currNode := s.arena.at(curr)
+			if currNode.marked() {
+				curr, _ = unpack(atomic.LoadUint64(&currNode.next[level]))
+				continue
+			}
+			if currNode.key.Less(key) {
+				pred = currNode
+				curr = s.nextOffset(pred, level)
+				continue
+			}
+			break
+		}
+	}
+
+	curr := s.nextOffset(pred, 0)
+	if curr == nilOffset {
+		return nil, false
+	}
+
+	n := s.arena.at(curr)
+	if n.marked() || n.key.Great(key) || n.key.Less(key) {
+		return nil, false
+	}
+
+	return *n.value.Load().(*interface{}), true
+}
+
+// Set inserts value under key, or overwrites it in place if key is already
+// present (the new value need not share a concrete type with the old one).
+// Returns an error only if the arena is full and key did not already exist.
+func (s *SkipList) Set(key Skey, value interface{}) error {
+	level := s.randLevel()
+
+	for {
+		preds, succs, found := s.search(key)
+		if found != nilOffset {
+			s.arena.at(found).value.Store(&value)
+			return nil
+		}
+
+		n := newNode(key, value, level)
+		offset, ok := s.arena.alloc(n)
+		if !ok {
+			return ErrArenaFull{}
+		}
+		for i := range n.next {
+			n.next[i] = pack(succs[i], false)
+		}
+
+		// Level 0 is the linearization point: once this CAS lands, concurrent
+		// Gets can observe the new node. If it fails, a nearby insert/delete
+		// raced us; retry the whole operation from scratch.
+		if !atomic.CompareAndSwapUint64(&preds[0].next[0], pack(succs[0], false), pack(offset, false)) {
+			continue
+		}
+
+		for i := 1; i < level; i++ {
+			for {
+				if atomic.CompareAndSwapUint64(&preds[i].next[i], pack(succs[i], false), pack(offset, false)) {
+					break
+				}
+				// This level's predecessor changed; re-search just to refresh it
+				// rather than restarting the whole insert (the node is already
+				// visible and correct at every level below i).
+				preds, succs, _ = s.search(key)
+				n.next[i] = pack(succs[i], false)
+			}
+		}
+
+		atomic.AddInt64(&s.length, 1)
+		return nil
+	}
+}
+
+// Remove deletes key, returning true if it was present. It marks the node's
+// bottom-level pointer (the linearization point for "this key is gone") and then
+// helps physically unlink it; any other goroutine's concurrent search that passes
+// through the marked node will finish the unlink if this one doesn't.
+func (s *SkipList) Remove(key Skey) bool {
+	for {
+		_, succs, found := s.search(key)
+		if found == nilOffset {
+			return false
+		}
+
+		n := s.arena.at(found)
+		next := atomic.LoadUint64(&n.next[0])
+		if _, marked := unpack(next); marked {
+			return false // someone else already removed it
+		}
+
+		offset, _ := unpack(next)
+		if !atomic.CompareAndSwapUint64(&n.next[0], next, pack(offset, true)) {
+			continue // bottom pointer changed underneath us; retry
+		}
+
+		atomic.AddInt64(&s.length, -1)
+		s.search(key) // help unlink at every level we can reach
+		_ = succs
+		return true
+	}
+}
+
+// nextOffset reads the offset component of n's level-i next pointer.
+func (s *SkipList) nextOffset(n *node, level int) uint32 {
+	offset, _ := unpack(atomic.LoadUint64(&n.next[level]))
+	return offset
+}
+
+// search finds, at every level, the last node strictly less than key (preds) and
+// the first node not less than key (succs), physically unlinking any marked node
+// it passes along the way. found is the arena offset of an exact key match at
+// level 0, or nilOffset.
+//
+// If an unlink CAS fails, pred can no longer be trusted: either a concurrent
+// insert landed right after it, or pred itself has since been marked and its own
+// removal is in flight, in which case retrying the same CAS against it would never
+// succeed again and the loop would spin forever. Either way the fix is the classic
+// Harris/Fraser one: abandon pred and restart the whole search from the head
+// rather than resuming against it, so this goroutine always searches over a
+// still-live predecessor chain and any number of failed CASes bounds out in a
+// finite number of restarts.
+func (s *SkipList) search(key Skey) (preds []*node, succs []uint32, found uint32) {
+	preds = make([]*node, s.maxLevel)
+	succs = make([]uint32, s.maxLevel)
+
+restart:
+	pred := s.head
+	for level := s.maxLevel - 1; level >= 0; level-- {
+		curr := s.nextOffset(pred, level)
+
+		for curr != nilOffset {
+			currNode := s.arena.at(curr)
+
+			if currNode.marked() {
+				nextOffset, _ := unpack(atomic.LoadUint64(&currNode.next[level]))
+				oldPredNext := pack(curr, false)
+				if !atomic.CompareAndSwapUint64(&pred.next[level], oldPredNext, pack(nextOffset, false)) {
+					goto restart
+				}
+				curr = nextOffset
+				continue
+			}
+
+			if currNode.key.Less(key) {
+				pred = currNode
+				curr = s.nextOffset(pred, level)
+				continue
+			}
+
+			break
+		}
+
+		preds[level] = pred
+		succs[level] = curr
+	}
+
+	if succs[0] != nilOffset {
+		n := s.arena.at(succs[0])
+		if !n.key.Great(key) && !n.key.Less(key) && !n.marked() {
+			found = succs[0]
+		}
+	}
+
+	return preds, succs, found
+}
+
+// randLevel picks a tower height in [1, maxLevel] with ~1/4 chance of promotion
+// to each successive level, using a lock-free xorshift64 PRNG so level selection
+// never touches math/rand's global mutex.
+func (s *SkipList) randLevel() int {
+	level := 1
+	for level < s.maxLevel && s.nextRand()&3 == 0 {
+		level++
+	}
+	return level
+}
+
+func (s *SkipList) nextRand() uint64 {
+	for {
+		old := atomic.LoadUint64(&s.rng)
+		x := old
+		x ^= x << 13
+		x ^= x >> 7
+		x ^= x << 17
+		if atomic.CompareAndSwapUint64(&s.rng, old, x) {
+			return x
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package concurrent
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type intKey int64
+
+func (k intKey) Great(o Skey) bool  { return k > o.(intKey) }
+func (k intKey) GreatE(o Skey) bool { return k >= o.(intKey) }
+func (k intKey) Less(o Skey) bool   { return k < o.(intKey) }
+func (k intKey) LessE(o Skey) bool  { return k <= o.(intKey) }
+
+func TestBasicSetGetRemove(t *testing.T) {
+	s := New()
+
+	s.Set(intKey(10), "a")
+	s.Set(intKey(30), "c")
+	s.Set(intKey(20), "b")
+
+	if v, ok := s.Get(intKey(20)); !ok || v.(string) != "b" {
+		t.Fatalf("wrong value for key 20: %v %v", v, ok)
+	}
+
+	s.Set(intKey(20), "b2")
+	if v, ok := s.Get(intKey(20)); !ok || v.(string) != "b2" {
+		t.Fatalf("overwrite didn't take effect: %v %v", v, ok)
+	}
+
+	if !s.Remove(intKey(20)) {
+		t.Fatal("Remove on an existing key should report true")
+	}
+	if _, ok := s.Get(intKey(20)); ok {
+		t.Fatal("removed key should no longer be visible")
+	}
+	if s.Remove(intKey(20)) {
+		t.Fatal("double Remove should report false")
+	}
+
+	if s.Len() != 2 {
+		t.Fatalf("wrong length after remove: got %v", s.Len())
+	}
+}
+
+func TestConcurrentReadersAndWriters(t *testing.T) {
+	s := New()
+	const n = 20000
+	const writers = 4
+	const readers = 4
+
+	var wg sync.WaitGroup
+	wg.Add(writers + readers)
+
+	for w := 0; w < writers; w++ {
+		w := w
+		go func() {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				s.Set(intKey(w*n+i), i)
+			}
+		}()
+	}
+
+	for r := 0; r < readers; r++ {
+		go func() {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				s.Get(intKey(i))
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if s.Len() != writers*n {
+		t.Fatalf("wrong length after concurrent inserts: got %v want %v", s.Len(), writers*n)
+	}
+
+	for w := 0; w < writers; w++ {
+		for i := 0; i < n; i += n / 10 {
+			if v, ok := s.Get(intKey(w*n + i)); !ok || v.(int) != i {
+				t.Fatalf("missing or wrong value for key %v: %v %v", w*n+i, v, ok)
+			}
+		}
+	}
+}
+
+func TestConcurrentRemovesAreMutuallyExclusive(t *testing.T) {
+	s := New()
+	const n = 5000
+	for i := 0; i < n; i++ {
+		s.Set(intKey(i), i)
+	}
+
+	var successCount int64
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	const removers = 8
+
+	wg.Add(removers)
+	for g := 0; g < removers; g++ {
+		go func() {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				if s.Remove(intKey(i)) {
+					mu.Lock()
+					successCount++
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if successCount != n {
+		t.Fatalf("expected exactly %d successful removes (one per key), got %d", n, successCount)
+	}
+	if s.Len() != 0 {
+		t.Fatalf("expected empty list after removing everything, got len %v", s.Len())
+	}
+}
+
+func TestSetOverwriteWithDifferentConcreteType(t *testing.T) {
+	s := New()
+
+	s.Set(intKey(1), 42)
+	if v, ok := s.Get(intKey(1)); !ok || v.(int) != 42 {
+		t.Fatalf("wrong initial value: %v %v", v, ok)
+	}
+
+	s.Set(intKey(1), "x") // must not panic despite the type change
+	if v, ok := s.Get(intKey(1)); !ok || v.(string) != "x" {
+		t.Fatalf("overwrite with a different concrete type didn't take effect: %v %v", v, ok)
+	}
+}
+
+// TestConcurrentSetRemoveOverlappingKeys hammers a small, shared keyspace with
+// mixed Set/Remove/Get from many goroutines at once, so predecessors are
+// constantly being marked out from under goroutines that are mid-search over
+// them. Unlike TestConcurrentReadersAndWriters/TestConcurrentRemovesAreMutuallyExclusive,
+// which give each goroutine a disjoint slice of keys, this is the access pattern
+// that actually exercises search's unlink-CAS-failure path.
+func TestConcurrentSetRemoveOverlappingKeys(t *testing.T) {
+	s := New()
+	const keys = 64
+	const goroutines = 16
+	const opsPerGoroutine = 4000
+
+	var wg sync.WaitGroup
+	wg.Add(goroutines)
+	for g := 0; g < goroutines; g++ {
+		g := g
+		go func() {
+			defer wg.Done()
+			for i := 0; i < opsPerGoroutine; i++ {
+				k := intKey((g*7 + i) % keys)
+				switch i % 3 {
+				case 0:
+					s.Set(k, i)
+				case 1:
+					s.Remove(k)
+				default:
+					s.Get(k)
+				}
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(20 * time.Second):
+		t.Fatal("concurrent Set/Remove over an overlapping keyspace did not finish; looks like a livelock in search's unlink retry")
+	}
+}
+
+func TestArenaFull(t *testing.T) {
+	s := NewWithOptions(DefaultMaxLevel, 4)
+
+	for i := 0; i < 4; i++ {
+		if err := s.Set(intKey(i), i); err != nil {
+			t.Fatalf("unexpected error filling arena: %v", err)
+		}
+	}
+
+	if err := s.Set(intKey(100), 100); err == nil {
+		t.Fatal("expected ErrArenaFull once the arena's capacity is exhausted")
+	}
+}
+
+func BenchmarkConcurrentSet(b *testing.B) {
+	b.ReportAllocs()
+	s := NewWithOptions(DefaultMaxLevel, b.N+1)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Set(intKey(i), i)
+	}
+}
+
+func BenchmarkConcurrentGet(b *testing.B) {
+	b.ReportAllocs()
+	s := New()
+	for i := 0; i <= b.N; i++ {
+		s.Set(intKey(i), i)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, ok := s.Get(intKey(i)); !ok {
+			b.Fatal("failed to Get an element that should exist")
+		}
+	}
+}
+
+func BenchmarkConcurrentSetParallel(b *testing.B) {
+	b.ReportAllocs()
+	s := NewWithOptions(DefaultMaxLevel, b.N+1)
+	var counter int64
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			n := atomic.AddInt64(&counter, 1)
+			s.Set(intKey(n), n)
+		}
+	})
+}
@@ -0,0 +1,223 @@
+package skiplist
+
+// Iterator provides ordered, read-only traversal over a SkipList, mirroring the shape
+// of goleveldb's iterator.Iterator: position with Seek/First/Last, walk with
+// Next/Prev until Valid reports false, then Release. An Iterator takes an RLock on
+// the list for its entire lifetime so that concurrent Sets/Removes can't produce or
+// observe a torn view while it's in use; Release must always be called to drop it.
+type Iterator struct {
+	list           *SkipList
+	current        *Element
+	lower          Skey // inclusive lower bound, nil means unbounded
+	upper          Skey // bound, nil means unbounded; see upperInclusive for [from, to] vs [from, to)
+	upperInclusive bool // true for a closed upper bound (RangeClosed), false for half-open (Range)
+	released       bool
+}
+
+// NewIterator returns an Iterator over the whole list. The returned iterator holds an
+// RLock on list until Release is called.
+func (list *SkipList) NewIterator() *Iterator {
+	return list.Range(nil, nil)
+}
+
+// Range returns an Iterator restricted to the half-open interval [from, to). Either
+// bound may be nil to leave that side unbounded. The returned iterator holds an RLock
+// on list until Release is called.
+func (list *SkipList) Range(from, to Skey) *Iterator {
+	list.mutex.RLock()
+	return &Iterator{list: list, lower: from, upper: to}
+}
+
+// RangeClosed returns an Iterator restricted to the closed interval [from, to], i.e.
+// to itself is included if present. Either bound may be nil to leave that side
+// unbounded. The returned iterator holds an RLock on list until Release is called.
+func (list *SkipList) RangeClosed(from, to Skey) *Iterator {
+	list.mutex.RLock()
+	return &Iterator{list: list, lower: from, upper: to, upperInclusive: true}
+}
+
+// Release releases the RLock taken on construction. It must be called exactly once,
+// and the iterator must not be used afterwards.
+func (it *Iterator) Release() {
+	if it.released {
+		return
+	}
+	it.released = true
+	it.current = nil
+	it.list.mutex.RUnlock()
+}
+
+// Valid reports whether the iterator is currently positioned at an element.
+func (it *Iterator) Valid() bool {
+	return it.current != nil
+}
+
+// Key returns the key of the current element. Only valid to call when Valid()
+// returns true.
+func (it *Iterator) Key() Skey {
+	return it.current.key
+}
+
+// Value returns the value of the current element. Only valid to call when Valid()
+// returns true.
+func (it *Iterator) Value() interface{} {
+	return it.current.value
+}
+
+// First positions the iterator at the first element within range.
+func (it *Iterator) First() bool {
+	return it.settle(skipTombstonesForward(it.seekLower()))
+}
+
+// Last positions the iterator at the last element within range.
+func (it *Iterator) Last() bool {
+	return it.settle(it.list.skipTombstonesBackward(it.seekUpper()))
+}
+
+// SeekGE positions the iterator at the first element with key >= the given key.
+func (it *Iterator) SeekGE(key Skey) bool {
+	return it.settle(skipTombstonesForward(it.list.seekGE(key)))
+}
+
+// SeekLT positions the iterator at the last element with key < the given key.
+func (it *Iterator) SeekLT(key Skey) bool {
+	return it.settle(it.list.skipTombstonesBackward(it.list.seekLT(key)))
+}
+
+// SeekForPrev positions the iterator at the last element with key <= the given key,
+// falling back to the element immediately before it if no exact match exists. It is
+// the natural counterpart to SeekGE when a caller wants to start walking backwards
+// from an inclusive bound, mirroring goleveldb's SeekForPrev.
+func (it *Iterator) SeekForPrev(key Skey) bool {
+	if e := it.list.seekGE(key); e != nil && !e.key.Great(key) && !e.tombstone {
+		return it.settle(e)
+	}
+	return it.settle(it.list.skipTombstonesBackward(it.list.seekLT(key)))
+}
+
+// Next advances the iterator to the next element within range, returning false once
+// the end of the range is reached.
+func (it *Iterator) Next() bool {
+	if it.current == nil {
+		return false
+	}
+	return it.settle(skipTombstonesForward(it.current.next[0]))
+}
+
+// Prev moves the iterator to the preceding element within range. The underlying list
+// only links forward, so this re-walks from the head to find the predecessor,
+// costing an extra O(log N) search per call.
+func (it *Iterator) Prev() bool {
+	if it.current == nil {
+		return false
+	}
+	return it.settle(it.list.skipTombstonesBackward(it.list.seekLT(it.current.key)))
+}
+
+// seekLower returns the first in-range candidate element for First().
+func (it *Iterator) seekLower() *Element {
+	if it.lower != nil {
+		return it.list.seekGE(it.lower)
+	}
+	return it.list.Front()
+}
+
+// seekUpper returns the last in-range candidate element for Last().
+func (it *Iterator) seekUpper() *Element {
+	if it.upper != nil {
+		if it.upperInclusive {
+			return it.list.seekLE(it.upper)
+		}
+		return it.list.seekLT(it.upper)
+	}
+	return it.list.last()
+}
+
+// settle positions the iterator at e if it falls within range, clearing it otherwise.
+func (it *Iterator) settle(e *Element) bool {
+	if e != nil && (it.lower != nil && e.key.Less(it.lower)) {
+		e = nil
+	}
+	if e != nil && it.upper != nil {
+		if it.upperInclusive {
+			if e.key.Great(it.upper) {
+				e = nil
+			}
+		} else if !e.key.Less(it.upper) {
+			e = nil
+		}
+	}
+	it.current = e
+	return it.Valid()
+}
+
+// seekGE returns the first element with key >= target, or nil if none exists.
+func (list *SkipList) seekGE(target Skey) *Element {
+	var prev = &list.elementNode
+	var next *Element
+
+	for i := list.maxLevel - 1; i >= 0; i-- {
+		next = prev.next[i]
+
+		for next != nil && next.key.Less(target) {
+			prev = &next.elementNode
+			next = next.next[i]
+		}
+	}
+
+	return next
+}
+
+// seekLE returns the last element with key <= target, or nil if none exists.
+func (list *SkipList) seekLE(target Skey) *Element {
+	if e := list.seekGE(target); e != nil && !e.key.Great(target) {
+		return e
+	}
+	return list.seekLT(target)
+}
+
+// seekLT returns the last element with key < target, or nil if none exists.
+func (list *SkipList) seekLT(target Skey) *Element {
+	var prev = &list.elementNode
+	var last *Element
+
+	for i := list.maxLevel - 1; i >= 0; i-- {
+		next := prev.next[i]
+
+		for next != nil && next.key.Less(target) {
+			prev = &next.elementNode
+			last = next
+			next = next.next[i]
+		}
+	}
+
+	return last
+}
+
+// skipTombstonesBackward returns the last non-tombstoned element at or before e,
+// re-searching from the head for each tombstone skipped since the list only links
+// forward.
+func (list *SkipList) skipTombstonesBackward(e *Element) *Element {
+	for e != nil && e.tombstone {
+		e = list.seekLT(e.key)
+	}
+	return e
+}
+
+// last returns the final (highest-keyed) element in the list, or nil if empty.
+func (list *SkipList) last() *Element {
+	var prev = &list.elementNode
+	var lastElement *Element
+
+	for i := list.maxLevel - 1; i >= 0; i-- {
+		next := prev.next[i]
+
+		for next != nil {
+			prev = &next.elementNode
+			lastElement = next
+			next = next.next[i]
+		}
+	}
+
+	return lastElement
+}
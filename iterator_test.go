@@ -0,0 +1,123 @@
+package skiplist
+
+import "testing"
+
+func buildIterList() *SkipList {
+	list := New()
+	for _, k := range []int64{50, 10, 40, 20, 30} {
+		list.Set(MySkey(k), k*10)
+	}
+	return list
+}
+
+func TestIteratorFullScan(t *testing.T) {
+	list := buildIterList()
+
+	it := list.NewIterator()
+	defer it.Release()
+
+	var got []int64
+	for ok := it.First(); ok; ok = it.Next() {
+		got = append(got, int64(it.Key().(MySkey)))
+	}
+
+	want := []int64{10, 20, 30, 40, 50}
+	if len(got) != len(want) {
+		t.Fatalf("wrong number of elements: got %v want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("wrong order: got %v want %v", got, want)
+		}
+	}
+}
+
+func TestIteratorRange(t *testing.T) {
+	list := buildIterList()
+
+	it := list.Range(MySkey(20), MySkey(50))
+	defer it.Release()
+
+	var got []int64
+	for ok := it.First(); ok; ok = it.Next() {
+		got = append(got, int64(it.Key().(MySkey)))
+	}
+
+	want := []int64{20, 30, 40}
+	if len(got) != len(want) {
+		t.Fatalf("wrong range scan: got %v want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("wrong range scan order: got %v want %v", got, want)
+		}
+	}
+}
+
+func TestIteratorRangeClosed(t *testing.T) {
+	list := buildIterList()
+
+	it := list.RangeClosed(MySkey(20), MySkey(50))
+	defer it.Release()
+
+	var got []int64
+	for ok := it.First(); ok; ok = it.Next() {
+		got = append(got, int64(it.Key().(MySkey)))
+	}
+
+	want := []int64{20, 30, 40, 50}
+	if len(got) != len(want) {
+		t.Fatalf("wrong closed range scan: got %v want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("wrong closed range scan order: got %v want %v", got, want)
+		}
+	}
+
+	if last := it.Last(); !last || it.Key().(MySkey) != MySkey(50) {
+		t.Fatalf("Last should land on the closed upper bound itself, got ok=%v key=%v", last, it.Key())
+	}
+}
+
+func TestIteratorSeekAndPrev(t *testing.T) {
+	list := buildIterList()
+
+	it := list.NewIterator()
+	defer it.Release()
+
+	if !it.SeekGE(MySkey(25)) || it.Key().(MySkey) != 30 {
+		t.Fatalf("SeekGE(25) should land on 30, got %v", it.Key())
+	}
+
+	if !it.SeekLT(MySkey(30)) || it.Key().(MySkey) != 20 {
+		t.Fatalf("SeekLT(30) should land on 20, got %v", it.Key())
+	}
+
+	if !it.Last() || it.Key().(MySkey) != 50 {
+		t.Fatalf("Last() should land on 50, got %v", it.Key())
+	}
+
+	if !it.Prev() || it.Key().(MySkey) != 40 {
+		t.Fatalf("Prev() from 50 should land on 40, got %v", it.Key())
+	}
+
+	if !it.SeekForPrev(MySkey(35)) || it.Key().(MySkey) != 30 {
+		t.Fatalf("SeekForPrev(35) should land on 30, got %v", it.Key())
+	}
+
+	if !it.SeekForPrev(MySkey(30)) || it.Key().(MySkey) != 30 {
+		t.Fatalf("SeekForPrev(30) should land on exact match 30, got %v", it.Key())
+	}
+}
+
+func TestIteratorEmptyRange(t *testing.T) {
+	list := buildIterList()
+
+	it := list.Range(MySkey(100), MySkey(200))
+	defer it.Release()
+
+	if it.First() {
+		t.Fatalf("expected empty range, got %v", it.Key())
+	}
+}
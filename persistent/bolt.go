@@ -0,0 +1,129 @@
+//go:build boltstore
+
+// This file is excluded from the default build (see the boltstore build tag)
+// because it pulls in go.etcd.io/bbolt, an optional dependency most callers of
+// this package won't want. Build with -tags boltstore once bbolt is in your
+// go.mod to use it.
+package persistent
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	elementsBucket = []byte("elements")
+	rootBucket     = []byte("root")
+	rootKey        = []byte("root")
+)
+
+// BoltListStore is a ListStore backed by a BoltDB/bbolt file, an example of
+// paging a SkipList's nodes to disk instead of keeping them all in memory.
+//
+// Element.Key and Element.Value are encoded with encoding/gob, which refuses to
+// encode or decode an interface value whose concrete type hasn't been registered
+// with gob.Register first. Callers must register every concrete Skey and value
+// type they plan to Set before the first SaveElement/SaveChanges, or encoding
+// will fail at runtime with "gob: type not registered for interface".
+type BoltListStore struct {
+	db *bolt.DB
+}
+
+// NewBoltListStore opens (creating if necessary) a BoltListStore at path.
+func NewBoltListStore(path string) (*BoltListStore, error) {
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(elementsBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(rootBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &BoltListStore{db: db}, nil
+}
+
+// Close closes the underlying bbolt database.
+func (s *BoltListStore) Close() error {
+	return s.db.Close()
+}
+
+// LoadElement implements ListStore.
+func (s *BoltListStore) LoadElement(id string) (*Element, error) {
+	var element Element
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		raw := tx.Bucket(elementsBucket).Get([]byte(id))
+		if raw == nil {
+			return fmt.Errorf("persistent: no such element %q", id)
+		}
+		return gob.NewDecoder(bytes.NewReader(raw)).Decode(&element)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &element, nil
+}
+
+// SaveElement implements ListStore. The concrete types behind element.Key and
+// element.Value must already be gob.Register'd (see BoltListStore's doc comment).
+func (s *BoltListStore) SaveElement(id string, element *Element) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(element); err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(elementsBucket).Put([]byte(id), buf.Bytes())
+	})
+}
+
+// DeleteElement implements ListStore.
+func (s *BoltListStore) DeleteElement(id string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(elementsBucket).Delete([]byte(id))
+	})
+}
+
+// LoadRoot implements ListStore.
+func (s *BoltListStore) LoadRoot() (*Element, error) {
+	var root *Element
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		raw := tx.Bucket(rootBucket).Get(rootKey)
+		if raw == nil {
+			return nil
+		}
+		root = &Element{}
+		return gob.NewDecoder(bytes.NewReader(raw)).Decode(root)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return root, nil
+}
+
+// SaveRoot implements ListStore. Same gob.Register requirement as SaveElement.
+func (s *BoltListStore) SaveRoot(root *Element) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(root); err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(rootBucket).Put(rootKey, buf.Bytes())
+	})
+}
@@ -0,0 +1,100 @@
+package persistent
+
+// lru is a small fixed-capacity, least-recently-used (LRU) cache of *Element keyed by
+// ID, sitting in front of a SkipList's ListStore so repeated traversals of hot
+// nodes don't have to round-trip to the backing store every time.
+type lru struct {
+	capacity int
+	items    map[string]*lruNode
+	head     *lruNode // most recently used
+	tail     *lruNode // least recently used
+}
+
+type lruNode struct {
+	id         string
+	element    *Element
+	prev, next *lruNode
+}
+
+func newLRU(capacity int) *lru {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &lru{capacity: capacity, items: make(map[string]*lruNode)}
+}
+
+func (c *lru) get(id string) (*Element, bool) {
+	n, ok := c.items[id]
+	if !ok {
+		return nil, false
+	}
+	c.moveToFront(n)
+	return n.element, true
+}
+
+func (c *lru) put(id string, element *Element) {
+	if n, ok := c.items[id]; ok {
+		n.element = element
+		c.moveToFront(n)
+		return
+	}
+
+	n := &lruNode{id: id, element: element}
+	c.items[id] = n
+	c.pushFront(n)
+
+	if len(c.items) > c.capacity {
+		c.evictTail()
+	}
+}
+
+func (c *lru) remove(id string) {
+	n, ok := c.items[id]
+	if !ok {
+		return
+	}
+	c.unlink(n)
+	delete(c.items, id)
+}
+
+func (c *lru) pushFront(n *lruNode) {
+	n.prev, n.next = nil, c.head
+	if c.head != nil {
+		c.head.prev = n
+	}
+	c.head = n
+	if c.tail == nil {
+		c.tail = n
+	}
+}
+
+func (c *lru) unlink(n *lruNode) {
+	if n.prev != nil {
+		n.prev.next = n.next
+	} else {
+		c.head = n.next
+	}
+	if n.next != nil {
+		n.next.prev = n.prev
+	} else {
+		c.tail = n.prev
+	}
+	n.prev, n.next = nil, nil
+}
+
+func (c *lru) moveToFront(n *lruNode) {
+	if c.head == n {
+		return
+	}
+	c.unlink(n)
+	c.pushFront(n)
+}
+
+func (c *lru) evictTail() {
+	n := c.tail
+	if n == nil {
+		return
+	}
+	c.unlink(n)
+	delete(c.items, n.id)
+}
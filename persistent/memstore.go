@@ -0,0 +1,68 @@
+package persistent
+
+import (
+	"fmt"
+	"sync"
+)
+
+// MemListStore is an in-memory ListStore. It gives a store-backed SkipList the
+// same fast-path behavior as keeping every node resident, which is useful for
+// tests, or as a drop-in starting point before wiring in a real backend such as
+// BoltListStore.
+type MemListStore struct {
+	mu       sync.Mutex
+	elements map[string]*Element
+	root     *Element
+}
+
+// NewMemListStore creates an empty in-memory ListStore.
+func NewMemListStore() *MemListStore {
+	return &MemListStore{elements: make(map[string]*Element)}
+}
+
+// LoadElement implements ListStore.
+func (s *MemListStore) LoadElement(id string) (*Element, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.elements[id]
+	if !ok {
+		return nil, fmt.Errorf("persistent: no such element %q", id)
+	}
+	return e, nil
+}
+
+// SaveElement implements ListStore.
+func (s *MemListStore) SaveElement(id string, element *Element) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.elements[id] = element
+	return nil
+}
+
+// DeleteElement implements ListStore.
+func (s *MemListStore) DeleteElement(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.elements, id)
+	return nil
+}
+
+// LoadRoot implements ListStore.
+func (s *MemListStore) LoadRoot() (*Element, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.root, nil
+}
+
+// SaveRoot implements ListStore.
+func (s *MemListStore) SaveRoot(root *Element) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.root = root
+	return nil
+}
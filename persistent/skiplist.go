@@ -0,0 +1,348 @@
+package persistent
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultMaxLevel is the maximum number of levels created for a new SkipList.
+	DefaultMaxLevel int = 18
+	// DefaultProbability is the default fraction of nodes promoted to the next level.
+	DefaultProbability float64 = 1 / math.E
+	// DefaultCacheCapacity is the default number of hot nodes the LRU in front of
+	// a SkipList's ListStore keeps resident.
+	DefaultCacheCapacity int = 10000
+)
+
+// SkipList is a skip list whose nodes are addressed by opaque ID and paged in
+// from a ListStore on demand, rather than all being resident as *Element
+// pointers. A small LRU sits in front of the store so repeatedly-touched nodes
+// don't round-trip to it on every traversal. Mutations are buffered in memory;
+// call SaveChanges to flush them, and HasChanges to check whether that's needed.
+type SkipList struct {
+	store       ListStore
+	maxLevel    int
+	length      int
+	probability float64
+	probTable   []float64
+	randSource  rand.Source
+	mutex       sync.RWMutex
+
+	root      *Element // the head sentinel; Key/Value unused, only Next matters
+	rootDirty bool
+	cache     *lru
+	dirty     map[string]*Element
+	deleted   map[string]bool
+	idCounter uint64
+}
+
+// New creates a SkipList backed by store, loading its root (or starting fresh if
+// store has none yet).
+func New(store ListStore) (*SkipList, error) {
+	return NewWithOptions(store, DefaultMaxLevel, DefaultCacheCapacity)
+}
+
+// NewWithOptions creates a SkipList backed by store with the given tower height
+// and LRU cache capacity.
+func NewWithOptions(store ListStore, maxLevel, cacheCapacity int) (*SkipList, error) {
+	if maxLevel < 1 || maxLevel > 64 {
+		panic("persistent: maxLevel must be a positive integer <= 64")
+	}
+
+	root, err := store.LoadRoot()
+	if err != nil {
+		return nil, err
+	}
+	rootDirty := false
+	if root == nil {
+		root = &Element{Next: make([]string, maxLevel)}
+		rootDirty = true
+	}
+
+	return &SkipList{
+		store:       store,
+		maxLevel:    maxLevel,
+		probability: DefaultProbability,
+		probTable:   probabilityTable(DefaultProbability, maxLevel),
+		randSource:  rand.New(rand.NewSource(time.Now().UnixNano())),
+		root:        root,
+		rootDirty:   rootDirty,
+		cache:       newLRU(cacheCapacity),
+		dirty:       make(map[string]*Element),
+		deleted:     make(map[string]bool),
+		// Seed from the wall clock so IDs stay unique across process restarts
+		// against the same store, not just within one process's lifetime.
+		idCounter: uint64(time.Now().UnixNano()),
+	}, nil
+}
+
+// Len returns the number of keys in the list.
+func (list *SkipList) Len() int {
+	list.mutex.RLock()
+	defer list.mutex.RUnlock()
+	return list.length
+}
+
+// HasChanges reports whether any node has been created, modified, or removed
+// since the last SaveChanges.
+func (list *SkipList) HasChanges() bool {
+	list.mutex.RLock()
+	defer list.mutex.RUnlock()
+	return len(list.dirty) > 0 || len(list.deleted) > 0 || list.rootDirty
+}
+
+// SaveChanges flushes every pending create/update/delete to the backing
+// ListStore. Callers control when this happens, so a burst of Sets/Removes can
+// be batched into one round of store writes instead of one per call.
+func (list *SkipList) SaveChanges() error {
+	list.mutex.Lock()
+	defer list.mutex.Unlock()
+
+	for id, element := range list.dirty {
+		if err := list.store.SaveElement(id, element); err != nil {
+			return err
+		}
+		delete(list.dirty, id)
+	}
+
+	for id := range list.deleted {
+		if err := list.store.DeleteElement(id); err != nil {
+			return err
+		}
+		delete(list.deleted, id)
+	}
+
+	if list.rootDirty {
+		if err := list.store.SaveRoot(list.root); err != nil {
+			return err
+		}
+		list.rootDirty = false
+	}
+
+	return nil
+}
+
+// Get returns the element for key, or nil if not present.
+func (list *SkipList) Get(key Skey) (*Element, error) {
+	list.mutex.Lock()
+	defer list.mutex.Unlock()
+
+	currentNext := list.root.Next
+
+	for i := list.maxLevel - 1; i >= 0; i-- {
+		nextID := currentNext[i]
+
+		for nextID != "" {
+			element, err := list.resolve(nextID)
+			if err != nil {
+				return nil, err
+			}
+			if !element.Key.Less(key) {
+				break
+			}
+			currentNext = element.Next
+			nextID = currentNext[i]
+		}
+	}
+
+	nextID := currentNext[0]
+	if nextID == "" {
+		return nil, nil
+	}
+
+	element, err := list.resolve(nextID)
+	if err != nil {
+		return nil, err
+	}
+	if element.Key.Great(key) {
+		return nil, nil
+	}
+
+	return element, nil
+}
+
+// Set inserts value under key, ordered by key, or updates the existing element
+// if key is already present.
+func (list *SkipList) Set(key Skey, value interface{}) error {
+	list.mutex.Lock()
+	defer list.mutex.Unlock()
+
+	prevIDs, prevNexts, err := list.getPrevElements(key)
+	if err != nil {
+		return err
+	}
+
+	if id := prevNexts[0][0]; id != "" {
+		element, err := list.resolve(id)
+		if err != nil {
+			return err
+		}
+		if !element.Key.Great(key) && !element.Key.Less(key) {
+			element.Value = value
+			list.markDirty(element)
+			return nil
+		}
+	}
+
+	level := list.randLevel()
+	id := list.newID()
+	element := &Element{ID: id, Key: key, Value: value, Next: make([]string, level)}
+
+	for i := 0; i < level; i++ {
+		element.Next[i] = prevNexts[i][i]
+
+		if prevIDs[i] == "" {
+			list.root.Next[i] = id
+			list.rootDirty = true
+			continue
+		}
+
+		prevElement, err := list.resolve(prevIDs[i])
+		if err != nil {
+			return err
+		}
+		prevElement.Next[i] = id
+		list.markDirty(prevElement)
+	}
+
+	list.cache.put(id, element)
+	list.markDirty(element)
+	list.length++
+	return nil
+}
+
+// Remove deletes the element for key, returning it, or nil if not present.
+func (list *SkipList) Remove(key Skey) (*Element, error) {
+	list.mutex.Lock()
+	defer list.mutex.Unlock()
+
+	prevIDs, prevNexts, err := list.getPrevElements(key)
+	if err != nil {
+		return nil, err
+	}
+
+	id := prevNexts[0][0]
+	if id == "" {
+		return nil, nil
+	}
+
+	element, err := list.resolve(id)
+	if err != nil {
+		return nil, err
+	}
+	if element.Key.Great(key) || element.Key.Less(key) {
+		return nil, nil
+	}
+
+	for i := range element.Next {
+		if prevIDs[i] == "" {
+			list.root.Next[i] = element.Next[i]
+			list.rootDirty = true
+			continue
+		}
+
+		prevElement, err := list.resolve(prevIDs[i])
+		if err != nil {
+			return nil, err
+		}
+		prevElement.Next[i] = element.Next[i]
+		list.markDirty(prevElement)
+	}
+
+	list.cache.remove(id)
+	delete(list.dirty, id)
+	list.deleted[id] = true
+	list.length--
+	return element, nil
+}
+
+// resolve loads element id from the LRU cache, falling back to the backing
+// store (and populating the cache) on a miss. id == "" (end of a level) resolves
+// to (nil, nil).
+func (list *SkipList) resolve(id string) (*Element, error) {
+	if id == "" {
+		return nil, nil
+	}
+
+	if element, ok := list.cache.get(id); ok {
+		return element, nil
+	}
+
+	if element, ok := list.dirty[id]; ok {
+		list.cache.put(id, element)
+		return element, nil
+	}
+
+	element, err := list.store.LoadElement(id)
+	if err != nil {
+		return nil, err
+	}
+	list.cache.put(id, element)
+	return element, nil
+}
+
+// markDirty records element as needing to be flushed by the next SaveChanges.
+func (list *SkipList) markDirty(element *Element) {
+	list.dirty[element.ID] = element
+	list.cache.put(element.ID, element)
+}
+
+// getPrevElements finds, at every level, the ID of the last element strictly
+// less than key (prevIDs, "" meaning the root) and that element's Next slice
+// (prevNexts), mirroring the root package's getPrevElementNodes but walking IDs
+// resolved through the store/cache instead of following in-memory pointers.
+func (list *SkipList) getPrevElements(key Skey) (prevIDs []string, prevNexts [][]string, err error) {
+	prevIDs = make([]string, list.maxLevel)
+	prevNexts = make([][]string, list.maxLevel)
+
+	currentID := ""
+	currentNext := list.root.Next
+
+	for i := list.maxLevel - 1; i >= 0; i-- {
+		nextID := currentNext[i]
+
+		for nextID != "" {
+			element, err := list.resolve(nextID)
+			if err != nil {
+				return nil, nil, err
+			}
+			if !element.Key.Less(key) {
+				break
+			}
+			currentID = nextID
+			currentNext = element.Next
+			nextID = currentNext[i]
+		}
+
+		prevIDs[i] = currentID
+		prevNexts[i] = currentNext
+	}
+
+	return prevIDs, prevNexts, nil
+}
+
+func (list *SkipList) newID() string {
+	return fmt.Sprintf("e%d", atomic.AddUint64(&list.idCounter, 1))
+}
+
+func (list *SkipList) randLevel() (level int) {
+	r := float64(list.randSource.Int63()) / (1 << 63)
+
+	level = 1
+	for level < list.maxLevel && r < list.probTable[level] {
+		level++
+	}
+	return
+}
+
+func probabilityTable(probability float64, maxLevel int) (table []float64) {
+	for i := 1; i <= maxLevel; i++ {
+		table = append(table, math.Pow(probability, float64(i-1)))
+	}
+	return table
+}
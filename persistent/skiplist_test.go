@@ -0,0 +1,156 @@
+package persistent
+
+import "testing"
+
+type intKey int64
+
+func (k intKey) Great(o Skey) bool  { return k > o.(intKey) }
+func (k intKey) GreatE(o Skey) bool { return k >= o.(intKey) }
+func (k intKey) Less(o Skey) bool   { return k < o.(intKey) }
+func (k intKey) LessE(o Skey) bool  { return k <= o.(intKey) }
+
+func newTestList(t *testing.T) (*SkipList, *MemListStore) {
+	t.Helper()
+	store := NewMemListStore()
+	list, err := New(store)
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	return list, store
+}
+
+func TestSetGetRemove(t *testing.T) {
+	list, _ := newTestList(t)
+
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	must(list.Set(intKey(10), "a"))
+	must(list.Set(intKey(30), "c"))
+	must(list.Set(intKey(20), "b"))
+
+	e, err := list.Get(intKey(20))
+	must(err)
+	if e == nil || e.Value.(string) != "b" {
+		t.Fatalf("wrong value for key 20: %v", e)
+	}
+
+	must(list.Set(intKey(20), "b2"))
+	e, err = list.Get(intKey(20))
+	must(err)
+	if e.Value.(string) != "b2" {
+		t.Fatalf("update didn't take effect: %v", e)
+	}
+
+	removed, err := list.Remove(intKey(20))
+	must(err)
+	if removed == nil || removed.Value.(string) != "b2" {
+		t.Fatalf("wrong removed element: %v", removed)
+	}
+
+	e, err = list.Get(intKey(20))
+	must(err)
+	if e != nil {
+		t.Fatalf("removed key should no longer be found: %v", e)
+	}
+
+	if list.Len() != 2 {
+		t.Fatalf("wrong length: got %v", list.Len())
+	}
+}
+
+func TestHasChangesAndSaveChanges(t *testing.T) {
+	list, store := newTestList(t)
+
+	// A brand new list has no root in the store yet, so it starts out dirty;
+	// flush that before asserting the steady state.
+	if err := list.SaveChanges(); err != nil {
+		t.Fatalf("SaveChanges returned error: %v", err)
+	}
+	if list.HasChanges() {
+		t.Fatal("list shouldn't report changes right after SaveChanges")
+	}
+
+	if err := list.Set(intKey(10), "a"); err != nil {
+		t.Fatalf("Set returned error: %v", err)
+	}
+	if !list.HasChanges() {
+		t.Fatal("list should report changes after a Set")
+	}
+
+	if err := list.SaveChanges(); err != nil {
+		t.Fatalf("SaveChanges returned error: %v", err)
+	}
+	if list.HasChanges() {
+		t.Fatal("list shouldn't report changes right after SaveChanges")
+	}
+
+	if root, err := store.LoadRoot(); err != nil || root == nil {
+		t.Fatalf("root should have been persisted: root=%v err=%v", root, err)
+	}
+}
+
+func TestReopenFromStore(t *testing.T) {
+	store := NewMemListStore()
+
+	list, err := New(store)
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	for _, k := range []int64{10, 30, 20, 50, 40} {
+		if err := list.Set(intKey(k), k*10); err != nil {
+			t.Fatalf("Set returned error: %v", err)
+		}
+	}
+	if err := list.SaveChanges(); err != nil {
+		t.Fatalf("SaveChanges returned error: %v", err)
+	}
+
+	reopened, err := New(store)
+	if err != nil {
+		t.Fatalf("reopening from store returned error: %v", err)
+	}
+
+	for _, k := range []int64{10, 20, 30, 40, 50} {
+		e, err := reopened.Get(intKey(k))
+		if err != nil {
+			t.Fatalf("Get returned error: %v", err)
+		}
+		if e == nil || e.Value.(int64) != k*10 {
+			t.Fatalf("wrong value for key %v after reopen: %v", k, e)
+		}
+	}
+}
+
+func TestLRUEviction(t *testing.T) {
+	store := NewMemListStore()
+	list, err := NewWithOptions(store, DefaultMaxLevel, 2)
+	if err != nil {
+		t.Fatalf("NewWithOptions returned error: %v", err)
+	}
+
+	for _, k := range []int64{10, 20, 30, 40, 50} {
+		if err := list.Set(intKey(k), k); err != nil {
+			t.Fatalf("Set returned error: %v", err)
+		}
+	}
+	if err := list.SaveChanges(); err != nil {
+		t.Fatalf("SaveChanges returned error: %v", err)
+	}
+
+	// With a cache capacity of 2 against 5 keys, most lookups must fall
+	// through to the store; this should still resolve correctly.
+	for _, k := range []int64{10, 20, 30, 40, 50} {
+		e, err := list.Get(intKey(k))
+		if err != nil {
+			t.Fatalf("Get returned error: %v", err)
+		}
+		if e == nil || e.Value.(int64) != k {
+			t.Fatalf("wrong value for key %v with a tiny cache: %v", k, e)
+		}
+	}
+}
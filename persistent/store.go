@@ -0,0 +1,36 @@
+// Package persistent provides a skip list whose nodes are addressed by opaque ID
+// rather than in-memory pointer, so they can be paged in from a KV backend
+// instead of all being resident at once — inspired by the SeaweedFS skip list,
+// which keeps only its top levels resident and defers the rest to disk.
+package persistent
+
+// Skey orders keys for the skip list. It mirrors the root package's Skey methods,
+// minus FilterValue. Its methods take this package's Skey, so a key type written
+// for the root package does not satisfy it as is.
+type Skey interface {
+	Great(other Skey) bool
+	GreatE(other Skey) bool
+	Less(other Skey) bool
+	LessE(other Skey) bool
+}
+
+// Element is the persisted representation of a skip list node: its key/value plus
+// the IDs of its per-level successors. An empty successor ID means "end of that
+// level".
+type Element struct {
+	ID    string
+	Key   Skey
+	Value interface{}
+	Next  []string
+}
+
+// ListStore persists a SkipList's nodes and root by opaque ID. SaveElement is
+// called for every node SaveChanges flushes, whether newly created or just
+// relinked, so a ListStore only needs to support upsert semantics.
+type ListStore interface {
+	LoadElement(id string) (*Element, error)
+	SaveElement(id string, element *Element) error
+	DeleteElement(id string) error
+	LoadRoot() (*Element, error)
+	SaveRoot(root *Element) error
+}
@@ -0,0 +1,433 @@
+package skiplist
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+const (
+	// DefaultMaxLevel is the maximum number of levels created for a new SkipList
+	DefaultMaxLevel int = 18
+	// DefaultProbability is the default fraction of nodes promoted to the next level
+	DefaultProbability float64 = 1 / math.E
+)
+
+// NewWithMaxLevel creates a new skip list with MaxLevel set to the provided number.
+// maxLevel has to be int(math.Ceil(math.Log(N))) for DefaultProbability (where N is
+// the upper bound on the number of elements in the skip list). See
+// http://web.archive.org/web/20050206091442/http://www.cs.uku.fi/~heljanko/sais/skip-lists
+// for more information.
+func NewWithMaxLevel(maxLevel int) *SkipList {
+	if maxLevel < 1 || maxLevel > 64 {
+		panic("maxLevel for a SkipList must be a positive integer <= 64")
+	}
+
+	return &SkipList{
+		elementNode:    elementNode{next: make([]*Element, maxLevel)},
+		maxLevel:       maxLevel,
+		randSource:     rand.New(rand.NewSource(time.Now().UnixNano())),
+		probability:    DefaultProbability,
+		probTable:      probabilityTable(DefaultProbability, maxLevel),
+		prevNodesCache: make([]*elementNode, maxLevel),
+	}
+}
+
+// New creates a new skip list with default parameters. Returns a pointer to the new list.
+func New() *SkipList {
+	return NewWithMaxLevel(DefaultMaxLevel)
+}
+
+// NewWithBloom creates a new skip list with default parameters, fronted by a counting
+// Bloom filter sized for expectedN keys at fpRate false positives. Get consults the
+// filter first and short-circuits to nil on a definite miss, skipping the O(log N)
+// traversal for the common lookup-of-absent-key case. expectedN and fpRate only size
+// the filter; the list has no fixed capacity and keeps working correctly (just with a
+// climbing false positive rate) past expectedN entries. Call Rebuild periodically under
+// heavy Set/Remove churn to keep the filter's counters from drifting.
+func NewWithBloom(expectedN int, fpRate float64) *SkipList {
+	list := NewWithMaxLevel(DefaultMaxLevel)
+	list.bloom = newCountingBloom(expectedN, fpRate)
+	return list
+}
+
+// Front returns the head node of the list. Skips over tombstoned versions kept
+// around for snapshots (see Snapshot).
+func (list *SkipList) Front() *Element {
+	return skipTombstonesForward(list.next[0])
+}
+
+// Set inserts a value in the list with the specified key, ordered by the key. If the
+// key exists, it updates the value in the existing node. Returns a pointer to the new element.
+// Holds the list's write lock for the whole call, search included.
+func (list *SkipList) Set(key Skey, value interface{}) *Element {
+	list.mutex.Lock()
+	defer list.mutex.Unlock()
+
+	return list.set(key, value)
+}
+
+// set inserts or updates key, assuming the caller already holds list.mutex for
+// writing. Split out of Set so Batch.Write can apply many operations under a single
+// lock acquisition.
+func (list *SkipList) set(key Skey, value interface{}) *Element {
+	var element *Element
+	prevs := list.getPrevElementNodes(key)
+
+	if element = prevs[0].next[0]; element != nil && !element.key.Great(key) && !element.key.Less(key) {
+		list.archiveVersion(element)
+		revived := element.tombstone
+		element.value = value
+		element.seq = list.nextSeq()
+		element.tombstone = false
+		if revived {
+			// Clear tombstone before touching the filter: if this addToBloom call
+			// hits a saturated counter, rebuildBloomLocked walks the list right away and
+			// must see the element as live, or the revived key would be dropped
+			// from the filter and Get would wrongly report it absent.
+			list.addToBloom(key)
+		}
+		return element
+	}
+
+	element = &Element{
+		elementNode: elementNode{
+			next: make([]*Element, list.randLevel()),
+		},
+		key:   key,
+		value: value,
+		seq:   list.nextSeq(),
+	}
+
+	for i := range element.next {
+		element.next[i] = prevs[i].next[i]
+		prevs[i].next[i] = element
+	}
+
+	list.Length++
+	list.addToBloom(key)
+	return element
+}
+
+// Get finds an element by key. It returns element pointer if found, nil if not found.
+// Holds the list's read lock for the whole call, search included.
+func (list *SkipList) Get(key Skey) *Element {
+	list.mutex.RLock()
+	defer list.mutex.RUnlock()
+
+	if list.bloom != nil && !list.bloom.mayContain(key.FilterValue()) {
+		return nil
+	}
+
+	var prev = &list.elementNode
+	var next *Element
+
+	for i := list.maxLevel - 1; i >= 0; i-- {
+		next = prev.next[i]
+
+		for next != nil && next.key.Less(key) {
+			prev = &next.elementNode
+			next = next.next[i]
+		}
+	}
+
+	if next != nil && !next.key.Great(key) && !next.tombstone {
+		return next
+	}
+
+	return nil
+}
+
+// Remove deletes an element from the list. Returns removed element pointer if found,
+// nil if not found. Holds the list's write lock for the whole call, search included.
+func (list *SkipList) Remove(key Skey) *Element {
+	list.mutex.Lock()
+	defer list.mutex.Unlock()
+
+	return list.remove(key)
+}
+
+// remove deletes key, assuming the caller already holds list.mutex for writing. Split
+// out of Remove so Batch.Write can apply many operations under a single lock
+// acquisition.
+func (list *SkipList) remove(key Skey) *Element {
+	prevs := list.getPrevElementNodes(key)
+
+	element := prevs[0].next[0]
+	if element == nil || element.key.Great(key) || element.key.Less(key) || element.tombstone {
+		return nil
+	}
+
+	list.removeFromBloom(element.key)
+
+	if len(list.snapshotRefs) > 0 {
+		// A live snapshot predates this remove and may still need to resolve this
+		// key to its last live value, so leave the node linked as a tombstone; a
+		// later Snapshot.Release reclaims it once nothing could observe it anymore.
+		list.archiveVersion(element)
+		element.seq = list.nextSeq()
+		element.tombstone = true
+		list.Length--
+		return element
+	}
+
+	for k, v := range element.next {
+		prevs[k].next[k] = v
+	}
+
+	list.Length--
+	return element
+}
+
+// archiveVersion pushes element's current value onto its version history, so a live
+// Snapshot taken before this write can still resolve the key to it. No-op when no
+// snapshot could need it.
+func (list *SkipList) archiveVersion(element *Element) {
+	if len(list.snapshotRefs) == 0 {
+		return
+	}
+
+	element.older = &versionedValue{
+		seq:       element.seq,
+		value:     element.value,
+		tombstone: element.tombstone,
+		older:     element.older,
+	}
+}
+
+// addToBloom records key as present in the bloom filter, if one is enabled. If any of
+// key's counters was already saturated, the filter has drifted too far from reality to
+// trust, so it's rebuilt from the list's actual contents.
+func (list *SkipList) addToBloom(key Skey) {
+	if list.bloom == nil {
+		return
+	}
+	if list.bloom.add(key.FilterValue()) {
+		list.rebuildBloomLocked()
+	}
+}
+
+// removeFromBloom records key as no longer present in the bloom filter, if one is
+// enabled. Decrementing happens as soon as a key is logically removed (tombstoned or
+// physically unlinked alike): Get is the only reader of the filter, and a tombstoned
+// element is already invisible to Get regardless of whether reclaim has run yet.
+func (list *SkipList) removeFromBloom(key Skey) {
+	if list.bloom == nil {
+		return
+	}
+	list.bloom.remove(key.FilterValue())
+}
+
+// Rebuild recomputes the bloom filter from scratch by walking the list, correcting any
+// drift accumulated from counters saturating under heavy Set/Remove churn. No-op if the
+// list wasn't created with NewWithBloom.
+func (list *SkipList) Rebuild() {
+	list.mutex.Lock()
+	defer list.mutex.Unlock()
+
+	list.rebuildBloomLocked()
+}
+
+// rebuildBloomLocked is Rebuild's body, assuming the caller already holds list.mutex.
+func (list *SkipList) rebuildBloomLocked() {
+	if list.bloom == nil {
+		return
+	}
+
+	list.bloom.reset()
+	for e := list.next[0]; e != nil; e = e.next[0] {
+		if !e.tombstone {
+			list.bloom.add(e.key.FilterValue())
+		}
+	}
+}
+
+// RemoveByFilter deletes the first element whose key shares the same FilterValue as
+// the given key. Useful when callers only have a partial/derived identifier of the key
+// they want removed. Returns the removed element pointer if found, nil if not found.
+func (list *SkipList) RemoveByFilter(key Skey) *Element {
+	list.mutex.Lock()
+	defer list.mutex.Unlock()
+
+	filter := key.FilterValue()
+
+	var element *Element
+
+	for e := list.next[0]; e != nil; e = e.next[0] {
+		if !e.tombstone && e.key.FilterValue() == filter {
+			element = e
+			break
+		}
+	}
+
+	if element == nil {
+		return nil
+	}
+
+	list.removeFromBloom(element.key)
+
+	prevs := list.getPrevElementNodes(element.key)
+
+	if len(list.snapshotRefs) > 0 {
+		list.archiveVersion(element)
+		element.seq = list.nextSeq()
+		element.tombstone = true
+		list.Length--
+		return element
+	}
+
+	for k, v := range element.next {
+		prevs[k].next[k] = v
+	}
+
+	list.Length--
+	return element
+}
+
+// RemoveFront removes and returns the first (lowest-keyed) element of the list, or
+// nil if the list is empty.
+func (list *SkipList) RemoveFront() *Element {
+	list.mutex.Lock()
+	defer list.mutex.Unlock()
+
+	element := list.Front()
+	if element == nil {
+		return nil
+	}
+
+	list.removeFromBloom(element.key)
+
+	prevs := list.getPrevElementNodes(element.key)
+
+	if len(list.snapshotRefs) > 0 {
+		list.archiveVersion(element)
+		element.seq = list.nextSeq()
+		element.tombstone = true
+		list.Length--
+		return element
+	}
+
+	for k, v := range element.next {
+		prevs[k].next[k] = v
+	}
+
+	list.Length--
+	return element
+}
+
+// SetProbability changes the current P value of the list. It alters the probability of
+// elements being added to higher levels of the skip list.
+func (list *SkipList) SetProbability(newProbability float64) {
+	list.probability = newProbability
+	list.probTable = probabilityTable(list.probability, list.maxLevel)
+}
+
+func (list *SkipList) randLevel() (level int) {
+	// Our random number source only has Int63, so we have to produce a float64 from it
+	// Reference: https://golang.org/src/math/rand/rand.go#L150
+	r := float64(list.randSource.Int63()) / (1 << 63)
+
+	level = 1
+	for level < list.maxLevel && r < list.probTable[level] {
+		level++
+	}
+	return
+}
+
+// probabilityTable calculates in advance the probability of a new node having a given
+// level. probability is in [0, 1], MaxLevel is (0, 64]
+// Returns a table of floating point probabilities that each level should be included
+// during an insert.
+func probabilityTable(probability float64, maxLevel int) (table []float64) {
+	for i := 1; i <= maxLevel; i++ {
+		prob := math.Pow(probability, float64(i-1))
+		table = append(table, prob)
+	}
+	return table
+}
+
+// nextSeq hands out the next monotonically increasing sequence number, assigned to
+// every Set/Remove so Snapshot can pin a point-in-time view of the list.
+func (list *SkipList) nextSeq() uint64 {
+	list.seqCounter++
+	return list.seqCounter
+}
+
+// minSnapshotSeq returns the lowest sequence number of any currently live snapshot,
+// and whether any snapshot is live at all.
+func (list *SkipList) minSnapshotSeq() (seq uint64, any bool) {
+	for s := range list.snapshotRefs {
+		if !any || s < seq {
+			seq, any = s, true
+		}
+	}
+	return seq, any
+}
+
+// reclaim walks the list once, physically unlinking tombstones and dropping
+// version history that no live snapshot could observe anymore. A node's history is
+// safe to drop once every live snapshot's sequence number is already >= the node's
+// own, since each of them would resolve straight to the node's current version (or,
+// if it's a tombstone, correctly see the key as absent) without ever consulting
+// older. Called from Snapshot.Release, per-node cost is O(1) amortized over the
+// full list scan.
+func (list *SkipList) reclaim() {
+	min, anySnapshots := list.minSnapshotSeq()
+
+	prevs := make([]*elementNode, list.maxLevel)
+	for i := range prevs {
+		prevs[i] = &list.elementNode
+	}
+
+	for node := list.next[0]; node != nil; {
+		next := node.next[0]
+		reclaimable := !anySnapshots || node.seq <= min
+
+		if reclaimable {
+			node.older = nil
+		}
+
+		if node.tombstone && reclaimable {
+			for k, v := range node.next {
+				prevs[k].next[k] = v
+			}
+		} else {
+			for i := range node.next {
+				prevs[i] = &node.elementNode
+			}
+		}
+
+		node = next
+	}
+}
+
+// skipTombstonesForward returns the first non-tombstoned element starting at e,
+// walking forward along the bottom level.
+func skipTombstonesForward(e *Element) *Element {
+	for e != nil && e.tombstone {
+		e = e.next[0]
+	}
+	return e
+}
+
+// getPrevElementNodes returns the prevNodesCache used to build the Element's next
+// pointers, and also to find the final rank of the element inserted before.
+func (list *SkipList) getPrevElementNodes(key Skey) []*elementNode {
+	var prev = &list.elementNode
+	var next *Element
+
+	prevs := list.prevNodesCache
+
+	for i := list.maxLevel - 1; i >= 0; i-- {
+		next = prev.next[i]
+
+		for next != nil && next.key.Less(key) {
+			prev = &next.elementNode
+			next = next.next[i]
+		}
+
+		prevs[i] = prev
+	}
+
+	return prevs
+}
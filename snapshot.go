@@ -0,0 +1,100 @@
+package skiplist
+
+// Snapshot is an immutable, point-in-time view of a SkipList, usable for Get and
+// range scans without holding the list's RWMutex for their duration. It mirrors
+// goleveldb's DB.GetSnapshot(): writes made to the list after the snapshot was
+// taken are invisible through it. Release must be called once the snapshot is no
+// longer needed, so the list can reclaim versions it was pinning.
+type Snapshot struct {
+	list     *SkipList
+	seq      uint64
+	released bool
+}
+
+// Snapshot captures the current state of list. While the returned Snapshot is live,
+// keys it could still observe are kept around (as tombstones/version history)
+// instead of being physically unlinked by later Removes.
+func (list *SkipList) Snapshot() *Snapshot {
+	list.mutex.Lock()
+	defer list.mutex.Unlock()
+
+	seq := list.seqCounter
+	if list.snapshotRefs == nil {
+		list.snapshotRefs = make(map[uint64]int)
+	}
+	list.snapshotRefs[seq]++
+
+	return &Snapshot{list: list, seq: seq}
+}
+
+// Release drops this snapshot's hold on the versions it could observe and
+// reclaims any that no other live snapshot still needs. It must be called exactly
+// once, and the snapshot (and any iterators taken from it) must not be used
+// afterwards.
+func (snap *Snapshot) Release() {
+	if snap.released {
+		return
+	}
+	snap.released = true
+
+	snap.list.mutex.Lock()
+	defer snap.list.mutex.Unlock()
+
+	if n := snap.list.snapshotRefs[snap.seq]; n <= 1 {
+		delete(snap.list.snapshotRefs, snap.seq)
+	} else {
+		snap.list.snapshotRefs[snap.seq] = n - 1
+	}
+
+	snap.list.reclaim()
+}
+
+// Get returns the highest-sequence live version of key that was visible as of the
+// snapshot, or nil if key didn't exist yet, or had already been removed, by then.
+func (snap *Snapshot) Get(key Skey) *Element {
+	snap.list.mutex.RLock()
+	defer snap.list.mutex.RUnlock()
+
+	node := snap.list.seekGE(key)
+	if node == nil || node.key.Great(key) || node.key.Less(key) {
+		return nil
+	}
+
+	return node.versionAt(snap.seq)
+}
+
+// versionAt returns a read-only view of e's value as it stood at seq, or nil if e
+// hadn't been written yet, or had already been removed, by seq. The returned
+// Element is a frozen copy detached from the list: its embedded next[0] is always
+// nil, so calling Next() on it always reports end-of-list rather than panicking on
+// an empty slice or, worse, walking the list's *current* (not snapshotted) links.
+func (e *Element) versionAt(seq uint64) *Element {
+	if e.seq <= seq {
+		if e.tombstone {
+			return nil
+		}
+		return frozenElement(e.key, e.value, e.seq)
+	}
+
+	for v := e.older; v != nil; v = v.older {
+		if v.seq <= seq {
+			if v.tombstone {
+				return nil
+			}
+			return frozenElement(e.key, v.value, v.seq)
+		}
+	}
+
+	return nil
+}
+
+// frozenElement builds a detached, read-only Element snapshot carrying a real
+// (one-element, always-nil) next slice so Element.Next() is safe to call on it.
+func frozenElement(key Skey, value interface{}, seq uint64) *Element {
+	return &Element{
+		elementNode: elementNode{next: []*Element{nil}},
+		key:         key,
+		value:       value,
+		seq:         seq,
+	}
+}
@@ -0,0 +1,170 @@
+package skiplist
+
+// SnapshotIterator provides ordered traversal over the state of a SkipList as it
+// stood when its Snapshot was taken. Unlike Iterator it does not hold the list's
+// RWMutex for its lifetime: each positioning call (First/Last/SeekGE/SeekLT/
+// SeekForPrev/Next/Prev) takes the RLock only for its own duration, so a long scan
+// doesn't block concurrent Sets between calls. Visibility of the keys it walks is
+// guaranteed by the parent Snapshot, which must stay live (not Released) for as
+// long as the iterator is used.
+type SnapshotIterator struct {
+	snap    *Snapshot
+	node    *Element // raw, possibly-stale node backing the current position
+	version *Element // node's value as resolved at snap.seq
+	lower   Skey
+	upper   Skey
+}
+
+// NewIterator returns a SnapshotIterator over the whole snapshot.
+func (snap *Snapshot) NewIterator() *SnapshotIterator {
+	return snap.Range(nil, nil)
+}
+
+// Range returns a SnapshotIterator restricted to the half-open interval [from, to).
+// Either bound may be nil to leave that side unbounded.
+func (snap *Snapshot) Range(from, to Skey) *SnapshotIterator {
+	return &SnapshotIterator{snap: snap, lower: from, upper: to}
+}
+
+// Valid reports whether the iterator is currently positioned at an element.
+func (it *SnapshotIterator) Valid() bool {
+	return it.node != nil
+}
+
+// Key returns the key of the current element. Only valid to call when Valid()
+// returns true.
+func (it *SnapshotIterator) Key() Skey {
+	return it.node.key
+}
+
+// Value returns the value of the current element as it stood at the snapshot.
+// Only valid to call when Valid() returns true.
+func (it *SnapshotIterator) Value() interface{} {
+	return it.version.value
+}
+
+// First positions the iterator at the first element within range.
+func (it *SnapshotIterator) First() bool {
+	it.snap.list.mutex.RLock()
+	defer it.snap.list.mutex.RUnlock()
+
+	var start *Element
+	if it.lower != nil {
+		start = it.snap.list.seekGE(it.lower)
+	} else {
+		start = it.snap.list.next[0]
+	}
+	return it.settle(skipForwardLocked(start, it.snap.seq))
+}
+
+// Last positions the iterator at the last element within range.
+func (it *SnapshotIterator) Last() bool {
+	it.snap.list.mutex.RLock()
+	defer it.snap.list.mutex.RUnlock()
+
+	var start *Element
+	if it.upper != nil {
+		start = it.snap.list.seekLT(it.upper)
+	} else {
+		start = it.snap.list.last()
+	}
+	return it.settle(skipBackwardLocked(start, it.snap.seq, it.snap.list))
+}
+
+// SeekGE positions the iterator at the first element with key >= the given key.
+func (it *SnapshotIterator) SeekGE(key Skey) bool {
+	it.snap.list.mutex.RLock()
+	defer it.snap.list.mutex.RUnlock()
+
+	return it.settle(skipForwardLocked(it.snap.list.seekGE(key), it.snap.seq))
+}
+
+// SeekLT positions the iterator at the last element with key < the given key.
+func (it *SnapshotIterator) SeekLT(key Skey) bool {
+	it.snap.list.mutex.RLock()
+	defer it.snap.list.mutex.RUnlock()
+
+	return it.settle(skipBackwardLocked(it.snap.list.seekLT(key), it.snap.seq, it.snap.list))
+}
+
+// SeekForPrev positions the iterator at the last element with key <= the given key,
+// falling back to the element immediately before it if no exact match was visible.
+func (it *SnapshotIterator) SeekForPrev(key Skey) bool {
+	it.snap.list.mutex.RLock()
+	defer it.snap.list.mutex.RUnlock()
+
+	if node := it.snap.list.seekGE(key); node != nil && !node.key.Great(key) {
+		if v := node.versionAt(it.snap.seq); v != nil {
+			return it.settle(node, v)
+		}
+	}
+	return it.settle(skipBackwardLocked(it.snap.list.seekLT(key), it.snap.seq, it.snap.list))
+}
+
+// Next advances the iterator to the next element within range, returning false
+// once the end of the range is reached.
+func (it *SnapshotIterator) Next() bool {
+	if it.node == nil {
+		return false
+	}
+
+	it.snap.list.mutex.RLock()
+	defer it.snap.list.mutex.RUnlock()
+
+	return it.settle(skipForwardLocked(it.node.next[0], it.snap.seq))
+}
+
+// Prev moves the iterator to the preceding element within range, re-walking from
+// the head to find it since the underlying list only links forward.
+func (it *SnapshotIterator) Prev() bool {
+	if it.node == nil {
+		return false
+	}
+
+	it.snap.list.mutex.RLock()
+	defer it.snap.list.mutex.RUnlock()
+
+	return it.settle(skipBackwardLocked(it.snap.list.seekLT(it.node.key), it.snap.seq, it.snap.list))
+}
+
+// settle positions the iterator at (node, version) if node falls within range and
+// has a version visible at the snapshot, clearing it otherwise. It accepts the
+// (nil, nil) pair produced by a failed skipForwardLocked/skipBackwardLocked search.
+func (it *SnapshotIterator) settle(node, version *Element) bool {
+	if node != nil && it.lower != nil && node.key.Less(it.lower) {
+		node, version = nil, nil
+	}
+	if node != nil && it.upper != nil && !node.key.Less(it.upper) {
+		node, version = nil, nil
+	}
+	it.node, it.version = node, version
+	return it.Valid()
+}
+
+// skipForwardLocked walks forward from node until it finds one with a version
+// visible at seq, returning both the raw node and its resolved version (or
+// (nil, nil) if the list is exhausted first). Callers must hold list's RLock (or
+// its write Lock) for the duration of the call.
+func skipForwardLocked(node *Element, seq uint64) (*Element, *Element) {
+	for node != nil {
+		if v := node.versionAt(seq); v != nil {
+			return node, v
+		}
+		node = node.next[0]
+	}
+	return nil, nil
+}
+
+// skipBackwardLocked walks backward from node (re-searching from the head at each
+// step, since the list only links forward) until it finds one with a version
+// visible at seq. Callers must hold list's RLock (or its write Lock) for the
+// duration of the call.
+func skipBackwardLocked(node *Element, seq uint64, list *SkipList) (*Element, *Element) {
+	for node != nil {
+		if v := node.versionAt(seq); v != nil {
+			return node, v
+		}
+		node = list.seekLT(node.key)
+	}
+	return nil, nil
+}
@@ -0,0 +1,200 @@
+package skiplist
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestSnapshotGetIsolatedFromLaterWrites(t *testing.T) {
+	list := New()
+	list.Set(MySkey(10), 1)
+	list.Set(MySkey(20), 2)
+
+	snap := list.Snapshot()
+	defer snap.Release()
+
+	list.Set(MySkey(10), 99)
+	list.Set(MySkey(30), 3)
+	list.Remove(MySkey(20))
+
+	if v := snap.Get(MySkey(10)); v == nil || v.Value().(int) != 1 {
+		t.Fatalf("snapshot should still see the old value for key 10, got %v", v)
+	}
+	if v := snap.Get(MySkey(20)); v == nil || v.Value().(int) != 2 {
+		t.Fatalf("snapshot should still see key 20 as present, got %v", v)
+	}
+	if v := snap.Get(MySkey(30)); v != nil {
+		t.Fatalf("snapshot should not see key 30 written after it was taken, got %v", v)
+	}
+
+	if v := list.Get(MySkey(10)); v == nil || v.Value().(int) != 99 {
+		t.Fatalf("live list should see the new value for key 10, got %v", v)
+	}
+	if v := list.Get(MySkey(20)); v != nil {
+		t.Fatalf("live list should see key 20 as removed, got %v", v)
+	}
+}
+
+func TestSnapshotGetValueFrozenAcrossLaterWrite(t *testing.T) {
+	list := New()
+	list.Set(MySkey(10), 1)
+
+	snap := list.Snapshot()
+	defer snap.Release()
+
+	e := snap.Get(MySkey(10))
+	if e == nil || e.Value().(int) != 1 {
+		t.Fatalf("expected snapshot value 1 before later write, got %v", e)
+	}
+
+	list.Set(MySkey(10), 99)
+
+	if v := e.Value().(int); v != 1 {
+		t.Fatalf("previously captured snapshot element should still read 1, got %v", v)
+	}
+}
+
+func TestSnapshotIteratorValueFrozenAcrossLaterWrite(t *testing.T) {
+	list := New()
+	list.Set(MySkey(10), 1)
+	list.Set(MySkey(20), 2)
+
+	snap := list.Snapshot()
+	defer snap.Release()
+
+	it := snap.NewIterator()
+	if ok := it.First(); !ok || it.Value().(int) != 1 {
+		t.Fatalf("expected snapshot iterator value 1 before later write, got ok=%v value=%v", ok, it.Value())
+	}
+
+	list.Set(MySkey(10), 99)
+
+	if v := it.Value().(int); v != 1 {
+		t.Fatalf("snapshot iterator positioned before the write should still read 1, got %v", v)
+	}
+}
+
+func TestSnapshotReclaimsAfterRelease(t *testing.T) {
+	list := New()
+	list.Set(MySkey(10), 1)
+
+	snap := list.Snapshot()
+	list.Remove(MySkey(10))
+
+	// Still tombstoned, not yet physically unlinked: the live view must hide it,
+	// but the node stays around because snap could still need it.
+	if list.Get(MySkey(10)) != nil {
+		t.Fatal("removed key should not be visible through the live list")
+	}
+	if list.Front() != nil {
+		t.Fatal("Front should skip the tombstoned node while snap is alive")
+	}
+
+	snap.Release()
+
+	if list.next[0] != nil {
+		t.Fatal("tombstoned node should have been physically unlinked after Release")
+	}
+}
+
+func TestSnapshotIteratorForward(t *testing.T) {
+	list := New()
+	list.Set(MySkey(10), 1)
+	list.Set(MySkey(20), 2)
+
+	snap := list.Snapshot()
+	defer snap.Release()
+
+	list.Set(MySkey(15), 15)
+	list.Remove(MySkey(10))
+
+	it := snap.NewIterator()
+
+	var got []int64
+	for ok := it.First(); ok; ok = it.Next() {
+		got = append(got, int64(it.Key().(MySkey)))
+	}
+
+	want := []int64{10, 20}
+	if len(got) != len(want) {
+		t.Fatalf("wrong snapshot scan: got %v want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("wrong snapshot scan order: got %v want %v", got, want)
+		}
+	}
+}
+
+func TestSnapshotElementNextDoesNotPanic(t *testing.T) {
+	list := New()
+	list.Set(MySkey(10), 1)
+
+	snap := list.Snapshot()
+	defer snap.Release()
+
+	e := snap.Get(MySkey(10))
+	if e == nil {
+		t.Fatal("expected to find key 10")
+	}
+	if n := e.Next(); n != nil {
+		t.Fatalf("Next() on a frozen snapshot element should report end-of-list, got %v", n)
+	}
+}
+
+func TestSnapshotIteratorConcurrentWithSet(t *testing.T) {
+	list := New()
+	for i := int64(0); i < 100; i++ {
+		list.Set(MySkey(i), i)
+	}
+
+	snap := list.Snapshot()
+	defer snap.Release()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		for i := int64(0); i < 1000; i++ {
+			list.Set(MySkey(i%100), i)
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		for n := 0; n < 50; n++ {
+			it := snap.NewIterator()
+			for ok := it.First(); ok; ok = it.Next() {
+				_ = it.Key()
+				_ = it.Value()
+			}
+		}
+	}()
+
+	wg.Wait()
+}
+
+func TestConcurrentSnapshotRefcounting(t *testing.T) {
+	list := New()
+	list.Set(MySkey(10), 1)
+
+	snapA := list.Snapshot()
+	snapB := list.Snapshot()
+
+	list.Remove(MySkey(10))
+
+	snapA.Release()
+	if list.next[0] == nil {
+		t.Fatal("node should still be reachable while snapB is alive")
+	}
+
+	if v := snapB.Get(MySkey(10)); v == nil || v.Value().(int) != 1 {
+		t.Fatalf("snapB should still see key 10, got %v", v)
+	}
+
+	snapB.Release()
+	if list.next[0] != nil {
+		t.Fatal("node should be reclaimed once every snapshot has released it")
+	}
+}
@@ -10,6 +10,7 @@ type Skey interface {
 	GreatE(skey Skey) bool
 	Less(skey Skey) bool
 	LessE(skey Skey) bool
+	FilterValue() string
 }
 
 type elementNode struct {
@@ -18,8 +19,11 @@ type elementNode struct {
 
 type Element struct {
 	elementNode
-	key   Skey
-	value interface{}
+	key       Skey
+	value     interface{}
+	seq       uint64          // sequence number this version was written at
+	tombstone bool            // true if this version is a Remove rather than a Set
+	older     *versionedValue // earlier versions of this key, newest first
 }
 
 // Key allows retrieval of the key for a given Element
@@ -32,10 +36,20 @@ func (e *Element) Value() interface{} {
 	return e.value
 }
 
-// Next returns the following Element or nil if we're at the end of the list.
-// Only operates on the bottom level of the skip list (a fully linked list).
+// Next returns the following Element or nil if we're at the end of the list. Skips
+// over tombstoned versions kept around for snapshots (see Snapshot). Only operates
+// on the bottom level of the skip list (a fully linked list).
 func (e *Element) Next() *Element {
-	return e.next[0]
+	return skipTombstonesForward(e.next[0])
+}
+
+// versionedValue records an older value of a key, kept around only while some live
+// Snapshot predates the write that superseded it. See SkipList.Snapshot.
+type versionedValue struct {
+	seq       uint64
+	value     interface{}
+	tombstone bool
+	older     *versionedValue
 }
 
 type SkipList struct {
@@ -47,4 +61,7 @@ type SkipList struct {
 	probTable      []float64
 	mutex          sync.RWMutex
 	prevNodesCache []*elementNode
+	seqCounter     uint64
+	snapshotRefs   map[uint64]int // active Snapshot sequence numbers -> refcount
+	bloom          *countingBloom // optional; nil unless created with NewWithBloom
 }